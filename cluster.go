@@ -0,0 +1,95 @@
+package rediscache
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// replaceHashScript thực hiện HSET(các field) -> RENAME -> PEXPIRE (và HDEL
+// dummy field nếu cần) atomically trên server, loại bỏ khoảng hở quan sát
+// được giữa các lệnh mà một reader hoặc keyspace-notification consumer có
+// thể thấy khi dùng pipeline (tmp key lộ ra, hoặc hai event tách rời).
+//
+// KEYS[1] = tmpKey, KEYS[2] = finalKey
+// ARGV[1] = TTL tính bằng milliseconds, ARGV[2] = "1" nếu map rỗng (dummy field)
+// ARGV[3:] = field, value, field, value, ... (bỏ qua khi dummy == "1")
+var replaceHashScript = redis.NewScript(`
+local tmp = KEYS[1]
+local final = KEYS[2]
+local ttlMs = tonumber(ARGV[1])
+local dummy = ARGV[2]
+
+if dummy == "1" then
+	redis.call("HSET", tmp, "___", "___")
+else
+	for i = 3, #ARGV, 2 do
+		redis.call("HSET", tmp, ARGV[i], ARGV[i + 1])
+	end
+end
+
+redis.call("RENAME", tmp, final)
+redis.call("PEXPIRE", final, ttlMs)
+
+if dummy == "1" then
+	redis.call("HDEL", final, "___")
+end
+
+return redis.status_reply("OK")
+`)
+
+// clusterSafeTmpKey sinh một tmp key chắc chắn nằm cùng slot Redis Cluster
+// với finalKey. RENAME giữa hai key khác slot sẽ lỗi trên cluster vì CRC16
+// của chúng khác nhau.
+//
+// Nếu finalKey đã có một cặp hash tag ("{...}"), suffix tmp được chèn ngay
+// sau dấu "}" đóng tag đó, giữ nguyên cặp tag đầu tiên (kể cả khi nội dung
+// tag rỗng, "{}") nên vị trí "{" đầu tiên mà scanner của Redis tìm thấy
+// không đổi. Quan trọng: không được rơi về nhánh "bọc cả finalKey trong
+// tag mới" khi tag rỗng — làm vậy sẽ chèn một dấu "{" MỚI phía trước cặp
+// "{}" sẵn có, khiến scanner dừng ở dấu "}" có sẵn đó và coi phần ở giữa
+// (chỉ một ký tự "{") là tag, thay vì tag dự định. Lưu ý: với tag rỗng,
+// Redis tính slot trên toàn bộ key, nên tmp key (đã thêm suffix) KHÔNG
+// cùng slot với finalKey trong trường hợp này.
+//
+// Nếu finalKey không có bất kỳ "{" nào, toàn bộ finalKey được bọc làm tag
+// cho tmp key ("{finalKey}:tmp:uuid"), khiến CRC16 của tag trùng với CRC16
+// của finalKey (vì không có tag, slot của finalKey vốn được tính trên toàn
+// bộ chuỗi). Nếu finalKey có "}" nhưng không có "{" nào, tag của tmp key
+// dừng ở "}" đó nên cũng không cùng slot với finalKey.
+func clusterSafeTmpKey(finalKey string) string {
+	suffix := ":tmp:" + uuid.NewString()
+
+	if open := strings.IndexByte(finalKey, '{'); open >= 0 {
+		if close := strings.IndexByte(finalKey[open+1:], '}'); close >= 0 {
+			tagEnd := open + 1 + close + 1
+			return finalKey[:tagEnd] + suffix + finalKey[tagEnd:]
+		}
+	}
+
+	return "{" + finalKey + "}" + suffix
+}
+
+// runAtomicReplaceHash chạy replaceHashScript để thay thế hash tại finalKey
+// bằng fields (dạng phẳng field, value, field, value, ...). Với fields rỗng,
+// hash cũ tại finalKey bị thay rồi xoá hẳn (Redis không giữ hash không còn
+// field nào), giống hành vi cũ dựa trên pipeline.
+func (c *Cache) runAtomicReplaceHash(ctx context.Context, finalKey string, fields []any) error {
+	rc, cancel := context.WithTimeout(ctx, c.Opts.PipelineTimeout)
+	defer cancel()
+
+	tmpKey := clusterSafeTmpKey(finalKey)
+
+	dummy := "0"
+	if len(fields) == 0 {
+		dummy = "1"
+	}
+
+	args := make([]any, 0, 2+len(fields))
+	args = append(args, c.Opts.TTL.Milliseconds(), dummy)
+	args = append(args, fields...)
+
+	return replaceHashScript.Run(rc, c.RDB, []string{tmpKey, finalKey}, args...).Err()
+}
@@ -0,0 +1,50 @@
+//go:build cluster
+
+package rediscache_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/chi07/rediscache"
+)
+
+// TestAtomicReplaceHash_RealCluster chạy với một Redis Cluster thật, chỉ định
+// qua biến môi trường REDISCACHE_CLUSTER_ADDRS (danh sách addr phân tách bởi
+// dấu phẩy). Bật bằng: go test -tags cluster ./...
+func TestAtomicReplaceHash_RealCluster(t *testing.T) {
+	addrs := os.Getenv("REDISCACHE_CLUSTER_ADDRS")
+	if addrs == "" {
+		t.Skip("REDISCACHE_CLUSTER_ADDRS not set; skipping real-cluster integration test")
+	}
+
+	rdb := redis.NewClusterClient(&redis.ClusterOptions{
+		Addrs: strings.Split(addrs, ","),
+	})
+	defer rdb.Close()
+
+	ctx := context.Background()
+	c := rediscache.NewUniversal(rdb, rediscache.Options{
+		TTL:             time.Minute,
+		KeyPrefix:       "rediscache_it",
+		PipelineTimeout: 2 * time.Second,
+	})
+
+	key := c.Key("group", "{9}", "members")
+	if err := c.AtomicReplaceHash(ctx, key, map[string]string{"user": "1"}); err != nil {
+		t.Fatalf("AtomicReplaceHash on real cluster error: %v", err)
+	}
+
+	h, err := rdb.HGetAll(ctx, key).Result()
+	if err != nil {
+		t.Fatalf("HGetAll error: %v", err)
+	}
+	if h["user"] != "1" {
+		t.Fatalf("unexpected hash content: %+v", h)
+	}
+}
@@ -0,0 +1,113 @@
+package rediscache
+
+import (
+	"strings"
+	"testing"
+)
+
+// crc16 triển khai CRC16-XMODEM mà Redis Cluster dùng để tính slot, chỉ
+// phục vụ việc kiểm tra tính đúng đắn của clusterSafeTmpKey trong test này.
+func crc16(data string) uint16 {
+	var crc uint16
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = (crc << 1) ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// slotTag trả về phần hash-tag của key nếu có ("{...}"), ngược lại trả về
+// toàn bộ key, mô phỏng cách Redis Cluster chọn dữ liệu để tính CRC16 slot.
+func slotTag(key string) string {
+	open := -1
+	for i := 0; i < len(key); i++ {
+		if key[i] == '{' {
+			open = i
+			break
+		}
+	}
+	if open < 0 {
+		return key
+	}
+	for j := open + 1; j < len(key); j++ {
+		if key[j] == '}' {
+			if j > open+1 {
+				return key[open+1 : j]
+			}
+			return key
+		}
+	}
+	return key
+}
+
+func slot(key string) uint16 {
+	return crc16(slotTag(key)) % 16384
+}
+
+func TestClusterSafeTmpKey_SameSlotWithoutExistingTag(t *testing.T) {
+	finalKey := "app:group:by_id"
+
+	tmpKey := clusterSafeTmpKey(finalKey)
+
+	if slot(tmpKey) != slot(finalKey) {
+		t.Fatalf("tmpKey %q (slot %d) not in same slot as finalKey %q (slot %d)",
+			tmpKey, slot(tmpKey), finalKey, slot(finalKey))
+	}
+}
+
+func TestClusterSafeTmpKey_SameSlotWithExistingTag(t *testing.T) {
+	finalKey := "app:group:{9}:members"
+
+	tmpKey := clusterSafeTmpKey(finalKey)
+
+	if slot(tmpKey) != slot(finalKey) {
+		t.Fatalf("tmpKey %q (slot %d) not in same slot as finalKey %q (slot %d)",
+			tmpKey, slot(tmpKey), finalKey, slot(finalKey))
+	}
+}
+
+func TestClusterSafeTmpKey_EmptyExistingTagKeepsTagBoundaries(t *testing.T) {
+	for _, finalKey := range []string{"{}foo", "foo{}bar"} {
+		tmpKey := clusterSafeTmpKey(finalKey)
+
+		open := strings.IndexByte(finalKey, '{')
+		close_ := strings.IndexByte(finalKey, '}')
+
+		// Trước fix, code rơi về nhánh "bọc cả finalKey trong tag mới" vì
+		// close (vị trí tương đối của "}") bằng 0 không thoả close > 0. Tag
+		// mới chèn thêm một dấu "{" phía trước cặp "{}" sẵn có, khiến
+		// scanner của Redis dừng ở "}" sẵn có và coi phần ở giữa (chỉ một
+		// ký tự "{") là tag — một hằng số không phụ thuộc finalKey, nên
+		// nhiều finalKey khác nhau rơi vào cùng một slot giả.
+		if got := slotTag(tmpKey); got == "{" {
+			t.Fatalf("clusterSafeTmpKey(%q) = %q; slotTag = %q picked up the stray embedded tag instead of falling back to the whole key",
+				finalKey, tmpKey, got)
+		}
+
+		// Cặp "{"/"}" gốc (dù rỗng) phải vẫn là cặp đầu tiên mà scanner thấy:
+		// không có "{" mới nào được chèn vào trước vị trí open.
+		if strings.IndexByte(tmpKey, '{') != open {
+			t.Fatalf("clusterSafeTmpKey(%q) = %q; first '{' moved from %d", finalKey, tmpKey, open)
+		}
+		if strings.IndexByte(tmpKey, '}') != close_ {
+			t.Fatalf("clusterSafeTmpKey(%q) = %q; first '}' moved from %d", finalKey, tmpKey, close_)
+		}
+	}
+}
+
+func TestClusterSafeTmpKey_Unique(t *testing.T) {
+	finalKey := "app:group:by_id"
+
+	a := clusterSafeTmpKey(finalKey)
+	b := clusterSafeTmpKey(finalKey)
+
+	if a == b {
+		t.Fatalf("expected distinct tmp keys across calls; got %q twice", a)
+	}
+}
@@ -0,0 +1,60 @@
+package rediscache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chi07/rediscache"
+)
+
+func TestNewUniversal_WorksLikeNew(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestCache(t)
+
+	// NewUniversal phải chấp nhận cùng kiểu redis.UniversalClient mà c.RDB đã là,
+	// và hoạt động giống hệt New đối với standalone client.
+	uc := rediscache.NewUniversal(c.RDB, rediscache.Options{
+		TTL:             2 * time.Minute,
+		KeyPrefix:       "test",
+		PipelineTimeout: 800 * time.Millisecond,
+	})
+
+	key := uc.Key("cluster", "tag")
+	if err := uc.AtomicReplaceHash(ctx, key, map[string]string{"a": "1"}); err != nil {
+		t.Fatalf("AtomicReplaceHash via NewUniversal error: %v", err)
+	}
+
+	h, err := uc.RDB.HGetAll(ctx, key).Result()
+	if err != nil {
+		t.Fatalf("HGetAll error: %v", err)
+	}
+	if h["a"] != "1" {
+		t.Fatalf("unexpected hash content: %+v", h)
+	}
+}
+
+func TestAtomicReplaceHash_WithHashTag(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestCache(t)
+
+	key := c.Key("group", "{9}", "members")
+
+	if err := c.AtomicReplaceHash(ctx, key, map[string]string{"user": "1"}); err != nil {
+		t.Fatalf("AtomicReplaceHash error: %v", err)
+	}
+
+	h, err := c.RDB.HGetAll(ctx, key).Result()
+	if err != nil {
+		t.Fatalf("HGetAll error: %v", err)
+	}
+	if h["user"] != "1" {
+		t.Fatalf("unexpected hash content: %+v", h)
+	}
+
+	for _, k := range mr.Keys() {
+		if k != key {
+			t.Fatalf("unexpected extra key left over: %s", k)
+		}
+	}
+}
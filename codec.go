@@ -0,0 +1,117 @@
+package rediscache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec điều khiển cách snapshot/hash values được encode trước khi ghi
+// xuống Redis và decode khi đọc lên. Cho phép người dùng đổi format
+// (JSON, MessagePack, nén gzip, ...) mà không phải fork thư viện.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	Name() string
+}
+
+// JSONCodec dùng encoding/json, là Codec mặc định nếu Options.Codec không set.
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+func (JSONCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+func (JSONCodec) Name() string { return "json" }
+
+// MsgpackCodec dùng MessagePack, payload nhỏ hơn và decode nhanh hơn JSON.
+type MsgpackCodec struct{}
+
+func (MsgpackCodec) Marshal(v any) ([]byte, error) { return msgpack.Marshal(v) }
+
+func (MsgpackCodec) Unmarshal(data []byte, v any) error { return msgpack.Unmarshal(data, v) }
+
+func (MsgpackCodec) Name() string { return "msgpack" }
+
+// gzipFlagRaw/gzipFlagGzip là byte đầu tiên mà GzipCodec ghi trước payload
+// của Inner để đánh dấu payload có bị nén hay không. Sniff theo magic number
+// của gzip (0x1f 0x8b) không đủ tin cậy: Inner có thể là một Codec bất kỳ,
+// và không có gì bảo đảm payload chưa nén của nó không bắt đầu bằng đúng
+// hai byte đó, khiến Unmarshal cố gunzip nhầm dữ liệu thô. Header tường
+// minh loại bỏ hoàn toàn sự nhập nhằng này.
+const (
+	gzipFlagRaw  byte = 0x00
+	gzipFlagGzip byte = 0x01
+)
+
+// GzipCodec bọc một Codec khác và nén payload bằng gzip khi nó vượt quá
+// Threshold byte. Payload không vượt quá Threshold được ghi nguyên văn (không nén)
+// để tránh overhead của gzip trên các giá trị nhỏ. Mọi payload đều được ghi
+// kèm 1 byte header (gzipFlagRaw/gzipFlagGzip) phía trước để Unmarshal biết
+// cách giải nén mà không cần đoán qua magic number.
+type GzipCodec struct {
+	Inner     Codec
+	Threshold int
+}
+
+func (g GzipCodec) Marshal(v any) ([]byte, error) {
+	b, err := g.Inner.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) <= g.Threshold {
+		return append([]byte{gzipFlagRaw}, b...), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(gzipFlagGzip)
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (g GzipCodec) Unmarshal(data []byte, v any) error {
+	if len(data) == 0 {
+		return g.Inner.Unmarshal(data, v)
+	}
+
+	flag, payload := data[0], data[1:]
+	if flag == gzipFlagGzip {
+		zr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+
+		raw, err := io.ReadAll(zr)
+		if err != nil {
+			return err
+		}
+		return g.Inner.Unmarshal(raw, v)
+	}
+	return g.Inner.Unmarshal(payload, v)
+}
+
+func (g GzipCodec) Name() string { return "gzip+" + g.Inner.Name() }
+
+// SetSnapshotAs là phiên bản generic của SetSnapshot, dùng codec đã cấu hình
+// trên c.Opts.Codec. Tồn tại song song với TryGetSnapshotAs để cặp
+// ghi/đọc có cùng kiểu tường minh T.
+func SetSnapshotAs[T any](ctx context.Context, c *Cache, key string, value T) error {
+	return c.SetSnapshot(ctx, key, value)
+}
+
+// TryGetSnapshotAs là generic GET + unmarshal ra T bằng codec đã cấu hình,
+// tương đương TryGetSnapshot nhưng đặt tên đối xứng với SetSnapshotAs.
+func TryGetSnapshotAs[T any](ctx context.Context, c *Cache, key string) (T, bool, error) {
+	return TryGetSnapshot[T](ctx, c, key)
+}
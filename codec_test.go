@@ -0,0 +1,139 @@
+package rediscache_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chi07/rediscache"
+)
+
+type codecGroup struct {
+	ID   int    `json:"id" msgpack:"id"`
+	Name string `json:"name" msgpack:"name"`
+}
+
+func TestJSONCodec_RoundTrip(t *testing.T) {
+	codec := rediscache.JSONCodec{}
+
+	b, err := codec.Marshal(codecGroup{ID: 9, Name: "Backend"})
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	var out codecGroup
+	if err := codec.Unmarshal(b, &out); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if out.ID != 9 || out.Name != "Backend" {
+		t.Fatalf("unexpected round-trip: %+v", out)
+	}
+	if codec.Name() != "json" {
+		t.Fatalf("Name() = %q; want json", codec.Name())
+	}
+}
+
+func TestMsgpackCodec_RoundTrip(t *testing.T) {
+	codec := rediscache.MsgpackCodec{}
+
+	b, err := codec.Marshal(codecGroup{ID: 3, Name: "Mobile"})
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	var out codecGroup
+	if err := codec.Unmarshal(b, &out); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if out.ID != 3 || out.Name != "Mobile" {
+		t.Fatalf("unexpected round-trip: %+v", out)
+	}
+	if codec.Name() != "msgpack" {
+		t.Fatalf("Name() = %q; want msgpack", codec.Name())
+	}
+}
+
+func TestGzipCodec_CompressesAboveThresholdOnly(t *testing.T) {
+	codec := rediscache.GzipCodec{Inner: rediscache.JSONCodec{}, Threshold: 64}
+
+	small, err := codec.Marshal(codecGroup{ID: 1, Name: "x"})
+	if err != nil {
+		t.Fatalf("Marshal(small) error: %v", err)
+	}
+	// Byte đầu là header (gzipFlagRaw = 0x00), không phải magic number gzip.
+	if strings.HasPrefix(string(small), "\x01\x1f\x8b") {
+		t.Fatalf("small payload should not be gzip-compressed")
+	}
+
+	big, err := codec.Marshal(codecGroup{ID: 2, Name: strings.Repeat("y", 200)})
+	if err != nil {
+		t.Fatalf("Marshal(big) error: %v", err)
+	}
+	// Byte đầu là header (gzipFlagGzip = 0x01), theo sau mới là magic number gzip.
+	if !strings.HasPrefix(string(big), "\x01\x1f\x8b") {
+		t.Fatalf("big payload should be gzip-compressed with an explicit header")
+	}
+
+	var out codecGroup
+	if err := codec.Unmarshal(big, &out); err != nil {
+		t.Fatalf("Unmarshal(big) error: %v", err)
+	}
+	if out.ID != 2 {
+		t.Fatalf("unexpected round-trip: %+v", out)
+	}
+}
+
+func TestGzipCodec_HeaderDisambiguatesMsgpackFromGzipMagic(t *testing.T) {
+	// Với Inner là MsgpackCodec, Unmarshal dựa vào header tường minh (không
+	// sniff gzip magic number 0x1f 0x8b) để đọc lại đúng cả payload thô lẫn
+	// payload đã nén. Payload thô ở đây có chứa byte 0x1f (positive fixint 31
+	// của ID) nhưng bắt đầu bằng byte map header của msgpack.
+	codec := rediscache.GzipCodec{Inner: rediscache.MsgpackCodec{}, Threshold: 1 << 20}
+
+	small, err := codec.Marshal(codecGroup{ID: 31, Name: ""})
+	if err != nil {
+		t.Fatalf("Marshal(small) error: %v", err)
+	}
+
+	var out codecGroup
+	if err := codec.Unmarshal(small, &out); err != nil {
+		t.Fatalf("Unmarshal(small) error: %v", err)
+	}
+	if out.ID != 31 {
+		t.Fatalf("unexpected round-trip: %+v", out)
+	}
+
+	big, err := codec.Marshal(codecGroup{ID: 32, Name: strings.Repeat("y", 200)})
+	if err != nil {
+		t.Fatalf("Marshal(big) error: %v", err)
+	}
+
+	var outBig codecGroup
+	if err := codec.Unmarshal(big, &outBig); err != nil {
+		t.Fatalf("Unmarshal(big) error: %v", err)
+	}
+	if outBig.ID != 32 {
+		t.Fatalf("unexpected round-trip: %+v", outBig)
+	}
+}
+
+func TestSetSnapshotAs_AndTryGetSnapshotAs_UseConfiguredCodec(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestCache(t)
+	c.Opts.Codec = rediscache.MsgpackCodec{}
+
+	key := c.Key("codec", "snapshot")
+	want := codecGroup{ID: 7, Name: "Data"}
+
+	if err := rediscache.SetSnapshotAs(ctx, c, key, want); err != nil {
+		t.Fatalf("SetSnapshotAs error: %v", err)
+	}
+
+	got, ok, err := rediscache.TryGetSnapshotAs[codecGroup](ctx, c, key)
+	if err != nil || !ok {
+		t.Fatalf("TryGetSnapshotAs error=%v ok=%v", err, ok)
+	}
+	if got.ID != 7 || got.Name != "Data" {
+		t.Fatalf("unexpected value: %+v", got)
+	}
+}
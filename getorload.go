@@ -0,0 +1,151 @@
+package rediscache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrNotFound là sentinel mà loader truyền cho GetOrLoad trả về khi dữ liệu
+// gốc không tồn tại, để GetOrLoad ghi negative cache thay vì coi đây là lỗi.
+var ErrNotFound = errors.New("rediscache: not found")
+
+// LoadOptions cấu hình hành vi read-through của GetOrLoad.
+type LoadOptions struct {
+	// TTL là thời gian sống của entry trong Redis. Mặc định c.Opts.TTL.
+	TTL time.Duration
+	// NegativeTTL là TTL (ngắn hơn) cho tombstone khi loader trả ErrNotFound.
+	// Mặc định TTL/10, tối thiểu 1 giây.
+	NegativeTTL time.Duration
+	// StaleTTL, nếu > 0, bật stale-while-revalidate: entry được coi là "fresh"
+	// trong StaleTTL kể từ lúc ghi; sau đó các lần đọc vẫn trả về giá trị cũ
+	// ngay lập tức nhưng kích hoạt một refresh nền.
+	StaleTTL time.Duration
+}
+
+func (o LoadOptions) withDefaults(c *Cache) LoadOptions {
+	if o.TTL <= 0 {
+		o.TTL = c.Opts.TTL
+	}
+	if o.NegativeTTL <= 0 {
+		o.NegativeTTL = o.TTL / 10
+		if o.NegativeTTL < time.Second {
+			o.NegativeTTL = time.Second
+		}
+	}
+	return o
+}
+
+// loadEnvelope là format lưu trữ nội bộ của GetOrLoad: bọc value thật cùng
+// cờ NotFound (negative cache) và FreshUntil (stale-while-revalidate).
+type loadEnvelope[T any] struct {
+	Value      T     `json:"value"`
+	FreshUntil int64 `json:"fresh_until,omitempty"` // unix nano; 0 nếu không dùng StaleTTL
+	NotFound   bool  `json:"not_found,omitempty"`
+}
+
+// refreshLockSuffix là suffix của key dùng để khoá việc refresh nền, tránh
+// nhiều node cùng gọi loader một lúc khi entry trở stale.
+const refreshLockSuffix = ":refresh-lock"
+
+// GetOrLoad đọc key từ Redis; nếu miss, coalesce các caller đồng thời bằng
+// singleflight rồi gọi loader đúng một lần, ghi lại kết quả và trả về cho
+// tất cả. loader trả ErrNotFound sẽ được cache dưới dạng tombstone trong
+// NegativeTTL để tránh liên tục dội vào nguồn dữ liệu gốc.
+//
+// Nếu opts.StaleTTL > 0, các lần đọc sau khi entry hết "fresh" nhưng còn
+// trong TTL cứng của Redis vẫn trả về giá trị cũ ngay lập tức, đồng thời
+// kích hoạt một refresh nền (qua distributed lock) để chỉ một node refresh
+// cho cả fleet.
+func GetOrLoad[T any](ctx context.Context, c *Cache, key string, loader func(ctx context.Context) (T, error), opts LoadOptions) (T, error) {
+	var zero T
+	opts = opts.withDefaults(c)
+
+	ctx, sp := c.startOp(ctx, "GetOrLoad", attribute.String("key", key))
+
+	env, ok, err := TryGetSnapshot[loadEnvelope[T]](ctx, c, key)
+	if err != nil {
+		sp.end(ctx, c, "GetOrLoad", err, -1)
+		return zero, err
+	}
+	if ok {
+		if env.NotFound {
+			c.recordHitMiss(ctx, "GetOrLoad", true)
+			sp.end(ctx, c, "GetOrLoad", ErrNotFound, -1)
+			return zero, ErrNotFound
+		}
+		if opts.StaleTTL > 0 && env.FreshUntil > 0 && time.Now().UnixNano() >= env.FreshUntil {
+			refreshStale(c, key, loader, opts)
+		}
+		c.recordHitMiss(ctx, "GetOrLoad", true)
+		sp.end(ctx, c, "GetOrLoad", nil, -1)
+		return env.Value, nil
+	}
+
+	c.recordHitMiss(ctx, "GetOrLoad", false)
+
+	v, err, _ := c.loadGroup.Do(key, func() (any, error) {
+		loaded, lErr := loader(ctx)
+		if errors.Is(lErr, ErrNotFound) {
+			_ = c.setEnvelope(ctx, key, loadEnvelope[T]{NotFound: true}, opts.NegativeTTL)
+			return zero, ErrNotFound
+		}
+		if lErr != nil {
+			return zero, lErr
+		}
+
+		env := loadEnvelope[T]{Value: loaded}
+		if opts.StaleTTL > 0 {
+			env.FreshUntil = time.Now().Add(opts.StaleTTL).UnixNano()
+		}
+		if sErr := c.setEnvelope(ctx, key, env, opts.TTL); sErr != nil {
+			return zero, sErr
+		}
+		return loaded, nil
+	})
+	sp.end(ctx, c, "GetOrLoad", err, -1)
+	if err != nil {
+		return zero, err
+	}
+	return v.(T), nil
+}
+
+// refreshStale chạy loader trong một goroutine nền, giữ bởi distributed lock
+// trên key+refreshLockSuffix nên chỉ một node trong fleet refresh tại một
+// thời điểm. Lỗi (kể cả ErrLocked khi node khác đang refresh) bị bỏ qua vì
+// caller đã nhận được giá trị stale ngay lập tức.
+func refreshStale[T any](c *Cache, key string, loader func(ctx context.Context) (T, error), opts LoadOptions) {
+	go func() {
+		ctx, sp := c.startOp(context.Background(), "GetOrLoadRefresh", attribute.String("key", key))
+
+		err := c.DoOnce(ctx, key+refreshLockSuffix, opts.TTL, func(ctx context.Context) error {
+			loaded, err := loader(ctx)
+			if err != nil {
+				return err
+			}
+
+			env := loadEnvelope[T]{Value: loaded, FreshUntil: time.Now().Add(opts.StaleTTL).UnixNano()}
+			return c.setEnvelope(ctx, key, env, opts.TTL)
+		})
+		sp.end(ctx, c, "GetOrLoadRefresh", err, -1)
+	}()
+}
+
+// setEnvelope marshal v bằng codec đã cấu hình và SET key với ttl tường
+// minh, khác SetSnapshot vốn luôn dùng c.Opts.TTL.
+func (c *Cache) setEnvelope(ctx context.Context, key string, v any, ttl time.Duration) error {
+	rc, cancel := context.WithTimeout(ctx, c.Opts.WriteTimeout)
+	defer cancel()
+
+	b, err := c.Opts.Codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	err = c.RDB.Set(rc, key, b, ttl).Err()
+	if err == nil {
+		c.invalidateLocal(key)
+	}
+	return err
+}
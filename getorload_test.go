@@ -0,0 +1,149 @@
+package rediscache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chi07/rediscache"
+)
+
+func TestGetOrLoad_MissCallsLoaderAndCaches(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestCache(t)
+
+	key := c.Key("getorload", "miss")
+	var calls int32
+
+	loader := func(ctx context.Context) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return "loaded", nil
+	}
+
+	v, err := rediscache.GetOrLoad(ctx, c, key, loader, rediscache.LoadOptions{})
+	if err != nil {
+		t.Fatalf("GetOrLoad error: %v", err)
+	}
+	if v != "loaded" {
+		t.Fatalf("GetOrLoad value = %q; want loaded", v)
+	}
+
+	// Lần thứ hai phải hit cache, không gọi lại loader
+	v2, err := rediscache.GetOrLoad(ctx, c, key, loader, rediscache.LoadOptions{})
+	if err != nil {
+		t.Fatalf("GetOrLoad #2 error: %v", err)
+	}
+	if v2 != "loaded" {
+		t.Fatalf("GetOrLoad #2 value = %q; want loaded", v2)
+	}
+	if calls != 1 {
+		t.Fatalf("loader called %d times; want 1", calls)
+	}
+}
+
+func TestGetOrLoad_CoalescesConcurrentMisses(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestCache(t)
+
+	key := c.Key("getorload", "coalesce")
+	var calls int32
+	release := make(chan struct{})
+
+	loader := func(ctx context.Context) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "loaded", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := rediscache.GetOrLoad(ctx, c, key, loader, rediscache.LoadOptions{}); err != nil {
+				t.Errorf("GetOrLoad error: %v", err)
+			}
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("loader called %d times; want 1", calls)
+	}
+}
+
+func TestGetOrLoad_NegativeCaching(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestCache(t)
+
+	key := c.Key("getorload", "negative")
+	var calls int32
+
+	loader := func(ctx context.Context) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return "", rediscache.ErrNotFound
+	}
+
+	_, err := rediscache.GetOrLoad(ctx, c, key, loader, rediscache.LoadOptions{NegativeTTL: time.Minute})
+	if !errors.Is(err, rediscache.ErrNotFound) {
+		t.Fatalf("GetOrLoad #1 error = %v; want ErrNotFound", err)
+	}
+
+	// Lần thứ hai phải đọc tombstone từ cache, không gọi lại loader
+	_, err = rediscache.GetOrLoad(ctx, c, key, loader, rediscache.LoadOptions{NegativeTTL: time.Minute})
+	if !errors.Is(err, rediscache.ErrNotFound) {
+		t.Fatalf("GetOrLoad #2 error = %v; want ErrNotFound", err)
+	}
+	if calls != 1 {
+		t.Fatalf("loader called %d times; want 1", calls)
+	}
+}
+
+func TestGetOrLoad_StaleWhileRevalidate(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestCache(t)
+
+	key := c.Key("getorload", "stale")
+	var calls int32
+
+	loader := func(ctx context.Context) (string, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 1 {
+			return "v1", nil
+		}
+		return "v2", nil
+	}
+
+	opts := rediscache.LoadOptions{TTL: time.Minute, StaleTTL: 10 * time.Millisecond}
+
+	v, err := rediscache.GetOrLoad(ctx, c, key, loader, opts)
+	if err != nil || v != "v1" {
+		t.Fatalf("GetOrLoad #1 = %q, err=%v; want v1", v, err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	// Entry hết fresh nhưng vẫn trong TTL cứng: trả về giá trị cũ ngay lập tức
+	// và kích hoạt refresh nền.
+	v, err = rediscache.GetOrLoad(ctx, c, key, loader, opts)
+	if err != nil || v != "v1" {
+		t.Fatalf("GetOrLoad #2 (stale read) = %q, err=%v; want v1", v, err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if atomic.LoadInt32(&calls) >= 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if atomic.LoadInt32(&calls) < 2 {
+		t.Fatalf("expected background refresh to call loader again; calls=%d", calls)
+	}
+}
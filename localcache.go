@@ -0,0 +1,142 @@
+package rediscache
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+// invalidateDebounce là khoảng thời gian gom các invalidateLocal liên tiếp
+// thành một message Pub/Sub duy nhất, tránh gửi một Publish riêng cho mỗi
+// key khi một batch write (vd SetSnapshotsMulti) đụng tới nhiều key.
+const invalidateDebounce = 20 * time.Millisecond
+
+// invalidationMsg là payload publish lên kênh invalidation. NodeID cho phép
+// publisher tự bỏ qua message của chính nó (nó đã evict local ngay khi ghi).
+type invalidationMsg struct {
+	NodeID string   `json:"node_id"`
+	Keys   []string `json:"keys"`
+}
+
+func localSnapshotKey(key string) string {
+	return "snap:" + key
+}
+
+func localHashKey(key, field string) string {
+	return "hash:" + key + ":" + field
+}
+
+// startLocalCache khởi tạo LRU trong process và subscribe kênh invalidation
+// của prefix hiện tại. Chỉ được gọi khi Opts.LocalCache.Size > 0 (xem newCache).
+func (c *Cache) startLocalCache() {
+	c.nodeID = uuid.NewString()
+	c.local = expirable.NewLRU[string, []byte](c.Opts.LocalCache.Size, nil, c.Opts.LocalCache.TTL)
+	c.pendingInvalidate = make(map[string]struct{})
+	c.sub = c.RDB.Subscribe(context.Background(), c.invalidationChannel())
+
+	go c.listenInvalidations()
+}
+
+// invalidationChannel là kênh Pub/Sub dùng để đồng bộ invalidation giữa các
+// Cache instance chia sẻ cùng KeyPrefix.
+func (c *Cache) invalidationChannel() string {
+	return c.Opts.KeyPrefix + ":invalidations"
+}
+
+// listenInvalidations đọc message từ kênh invalidation cho tới khi sub bị
+// Close() (Channel() đóng), evict các key liên quan khỏi LRU cục bộ.
+func (c *Cache) listenInvalidations() {
+	for msg := range c.sub.Channel() {
+		var inv invalidationMsg
+		if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+			continue
+		}
+		if inv.NodeID == c.nodeID {
+			continue // tự mình đã evict khi ghi, bỏ qua event của chính mình
+		}
+		for _, key := range inv.Keys {
+			c.evictLocal(key)
+		}
+	}
+}
+
+// localGet tra LRU cục bộ, trả về (nil, false) nếu local cache tắt hoặc miss.
+func (c *Cache) localGet(localKey string) ([]byte, bool) {
+	if c.local == nil {
+		return nil, false
+	}
+	return c.local.Get(localKey)
+}
+
+// localSet ghi một bản copy của raw vào LRU cục bộ (no-op nếu local cache tắt).
+func (c *Cache) localSet(localKey string, raw []byte) {
+	if c.local == nil {
+		return
+	}
+	cp := make([]byte, len(raw))
+	copy(cp, raw)
+	c.local.Add(localKey, cp)
+}
+
+// evictLocal xoá mọi entry LRU cục bộ bắt nguồn từ redis key, bao gồm cả
+// snapshot entry lẫn mọi hash-field entry của key đó. LRU không hỗ trợ xoá
+// theo prefix nên ta quét Keys() (bị giới hạn bởi LocalCache.Size, nên rẻ).
+func (c *Cache) evictLocal(key string) {
+	if c.local == nil {
+		return
+	}
+	snapKey := localSnapshotKey(key)
+	hashPrefix := "hash:" + key + ":"
+	for _, k := range c.local.Keys() {
+		if k == snapKey || strings.HasPrefix(k, hashPrefix) {
+			c.local.Remove(k)
+		}
+	}
+}
+
+// invalidateLocal evict key khỏi LRU cục bộ của instance hiện tại ngay lập
+// tức, rồi xếp hàng một thông báo invalidation (debounce invalidateDebounce)
+// để các instance khác cũng evict. No-op nếu local cache tắt.
+func (c *Cache) invalidateLocal(key string) {
+	if c.local == nil {
+		return
+	}
+	c.evictLocal(key)
+
+	c.invalidateMu.Lock()
+	c.pendingInvalidate[key] = struct{}{}
+	if c.invalidateTimer == nil {
+		c.invalidateTimer = time.AfterFunc(invalidateDebounce, c.flushInvalidations)
+	}
+	c.invalidateMu.Unlock()
+}
+
+// flushInvalidations publish một invalidationMsg gom toàn bộ key đang chờ
+// lên invalidationChannel, rồi reset hàng chờ.
+func (c *Cache) flushInvalidations() {
+	c.invalidateMu.Lock()
+	keys := make([]string, 0, len(c.pendingInvalidate))
+	for k := range c.pendingInvalidate {
+		keys = append(keys, k)
+	}
+	c.pendingInvalidate = make(map[string]struct{})
+	c.invalidateTimer = nil
+	c.invalidateMu.Unlock()
+
+	if len(keys) == 0 {
+		return
+	}
+
+	b, err := json.Marshal(invalidationMsg{NodeID: c.nodeID, Keys: keys})
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Opts.WriteTimeout)
+	defer cancel()
+	_ = c.RDB.Publish(ctx, c.invalidationChannel(), b).Err()
+}
@@ -0,0 +1,123 @@
+package rediscache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/chi07/rediscache"
+)
+
+// newLocalCacheTest tạo một Cache với LocalCache bật, trỏ vào addr của một
+// miniredis backend. Truyền cùng addr cho nhiều lời gọi để mô phỏng nhiều
+// node Cache chia sẻ một Redis thật.
+func newLocalCacheTest(t *testing.T, addr string) *rediscache.Cache {
+	t.Helper()
+
+	rdb := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	c := rediscache.New(rdb, rediscache.Options{
+		TTL:             2 * time.Minute,
+		KeyPrefix:       "test",
+		ReadTimeout:     300 * time.Millisecond,
+		WriteTimeout:    300 * time.Millisecond,
+		PipelineTimeout: 800 * time.Millisecond,
+		LocalCache:      rediscache.LocalCacheOptions{Size: 64},
+	})
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestLocalCache_HitsWithoutRoundTrippingRedis(t *testing.T) {
+	ctx := context.Background()
+	_, mr := newTestCache(t)
+	c := newLocalCacheTest(t, mr.Addr())
+
+	key := c.Key("snapshot", "course_1")
+	if err := c.SetSnapshot(ctx, key, map[string]string{"title": "Go"}); err != nil {
+		t.Fatalf("SetSnapshot error: %v", err)
+	}
+
+	got, ok, err := rediscache.TryGetSnapshot[map[string]string](ctx, c, key)
+	if err != nil || !ok {
+		t.Fatalf("TryGetSnapshot (warm local) error=%v ok=%v", err, ok)
+	}
+	if got["title"] != "Go" {
+		t.Fatalf("got %+v; want title=Go", got)
+	}
+
+	// Xoá key thẳng dưới Redis: nếu entry vẫn tới từ local LRU, lần đọc
+	// thứ hai vẫn phải hit dù backend đã trống.
+	if err := c.RDB.Del(ctx, key).Err(); err != nil {
+		t.Fatalf("Del error: %v", err)
+	}
+
+	got, ok, err = rediscache.TryGetSnapshot[map[string]string](ctx, c, key)
+	if err != nil || !ok {
+		t.Fatalf("TryGetSnapshot (local hit after Redis Del) error=%v ok=%v", err, ok)
+	}
+	if got["title"] != "Go" {
+		t.Fatalf("got %+v; want title=Go", got)
+	}
+}
+
+func TestLocalCache_WriteInvalidatesOwnEntry(t *testing.T) {
+	ctx := context.Background()
+	_, mr := newTestCache(t)
+	c := newLocalCacheTest(t, mr.Addr())
+
+	key := c.Key("snapshot", "course_2")
+	_ = c.SetSnapshot(ctx, key, map[string]string{"title": "v1"})
+
+	if _, ok, _ := rediscache.TryGetSnapshot[map[string]string](ctx, c, key); !ok {
+		t.Fatalf("expected initial snapshot to be readable")
+	}
+
+	if err := c.SetSnapshot(ctx, key, map[string]string{"title": "v2"}); err != nil {
+		t.Fatalf("SetSnapshot v2 error: %v", err)
+	}
+
+	got, ok, err := rediscache.TryGetSnapshot[map[string]string](ctx, c, key)
+	if err != nil || !ok {
+		t.Fatalf("TryGetSnapshot after overwrite error=%v ok=%v", err, ok)
+	}
+	if got["title"] != "v2" {
+		t.Fatalf("got %+v; want title=v2 (stale local entry not invalidated)", got)
+	}
+}
+
+func TestLocalCache_CrossInstanceInvalidation(t *testing.T) {
+	ctx := context.Background()
+	_, mr := newTestCache(t)
+	a := newLocalCacheTest(t, mr.Addr())
+	b := newLocalCacheTest(t, mr.Addr())
+
+	key := a.Key("snapshot", "course_3")
+	if err := a.SetSnapshot(ctx, key, map[string]string{"title": "v1"}); err != nil {
+		t.Fatalf("SetSnapshot error: %v", err)
+	}
+
+	if _, ok, err := rediscache.TryGetSnapshot[map[string]string](ctx, b, key); err != nil || !ok {
+		t.Fatalf("b: initial TryGetSnapshot error=%v ok=%v", err, ok)
+	}
+
+	if err := a.SetSnapshot(ctx, key, map[string]string{"title": "v2"}); err != nil {
+		t.Fatalf("SetSnapshot v2 error: %v", err)
+	}
+
+	// Chờ message invalidation (debounce + pubsub) tới instance b.
+	deadline := time.Now().Add(time.Second)
+	for {
+		got, ok, err := rediscache.TryGetSnapshot[map[string]string](ctx, b, key)
+		if err == nil && ok && got["title"] == "v2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("b never observed invalidation; got=%+v ok=%v err=%v", got, ok, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,137 @@
+package rediscache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrLocked là lỗi trả về khi một worker khác đang giữ lock.
+var ErrLocked = errors.New("rediscache: key is locked")
+
+// unlockScript chỉ DEL key nếu value hiện tại khớp với token của lock (compare-then-delete).
+var unlockScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+else
+	return 0
+end
+`)
+
+// refreshScript chỉ PEXPIRE key nếu value hiện tại khớp với token của lock.
+var refreshScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+else
+	return 0
+end
+`)
+
+// Lock là một handle cho một distributed lock được giữ bởi process hiện tại.
+// Mọi thao tác (Unlock, auto-refresh) đều được khoá bằng token để đảm bảo
+// chỉ holder gốc mới có thể release hoặc gia hạn lock.
+type Lock struct {
+	c     *Cache
+	key   string
+	token string
+	ttl   time.Duration
+
+	stopRefresh context.CancelFunc
+}
+
+// Lock cố gắng giành một distributed lock trên key bằng SET NX PX.
+// Trả về ErrLocked nếu một holder khác đang giữ lock.
+func (c *Cache) Lock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	ctx, sp := c.startOp(ctx, "Lock", attribute.String("key", key))
+
+	rc, cancel := context.WithTimeout(ctx, c.Opts.WriteTimeout)
+	defer cancel()
+
+	token := uuid.NewString()
+	ok, err := c.RDB.SetNX(rc, key, token, ttl).Result()
+	if err != nil {
+		sp.end(ctx, c, "Lock", err, -1)
+		return nil, err
+	}
+	if !ok {
+		sp.end(ctx, c, "Lock", ErrLocked, -1)
+		return nil, ErrLocked
+	}
+
+	sp.end(ctx, c, "Lock", nil, -1)
+	return &Lock{c: c, key: key, token: token, ttl: ttl}, nil
+}
+
+// Unlock chạy script compare-token-then-DEL để release lock. Nếu lock đã
+// hết hạn hoặc đã bị holder khác giành lại, Unlock không xoá gì và trả về nil.
+func (l *Lock) Unlock(ctx context.Context) error {
+	ctx, sp := l.c.startOp(ctx, "Unlock", attribute.String("key", l.key))
+
+	if l.stopRefresh != nil {
+		l.stopRefresh()
+		l.stopRefresh = nil
+	}
+
+	rc, cancel := context.WithTimeout(ctx, l.c.Opts.WriteTimeout)
+	defer cancel()
+
+	err := unlockScript.Run(rc, l.c.RDB, []string{l.key}, l.token).Err()
+	sp.end(ctx, l.c, "Unlock", err, -1)
+	return err
+}
+
+// WithAutoRefresh khởi động một goroutine gia hạn TTL của lock định kỳ
+// (mỗi ttl/3) bằng một script PEXPIRE-if-token-matches, cho tới khi Unlock
+// được gọi hoặc ctx bị cancel. Trả về chính l để có thể chain sau Lock().
+func (l *Lock) WithAutoRefresh(ctx context.Context) *Lock {
+	refreshCtx, cancel := context.WithCancel(ctx)
+	l.stopRefresh = cancel
+
+	interval := l.ttl / 3
+	if interval <= 0 {
+		interval = l.ttl
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-refreshCtx.Done():
+				return
+			case <-ticker.C:
+				tickCtx, sp := l.c.startOp(refreshCtx, "LockRefresh", attribute.String("key", l.key))
+				rc, rcCancel := context.WithTimeout(tickCtx, l.c.Opts.WriteTimeout)
+				err := refreshScript.Run(rc, l.c.RDB, []string{l.key}, l.token, l.ttl.Milliseconds()).Err()
+				rcCancel()
+				sp.end(tickCtx, l.c, "LockRefresh", err, -1)
+			}
+		}
+	}()
+
+	return l
+}
+
+// DoOnce giành lock trên key, chạy fn, rồi release lock. Trả về ErrLocked
+// nếu một worker khác đang giữ lock, phù hợp để bọc quanh các thao tác rebuild
+// cache (vd: SetSnapshot) nhằm tránh thundering-herd.
+func (c *Cache) DoOnce(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	ctx, sp := c.startOp(ctx, "DoOnce", attribute.String("key", key))
+
+	lock, err := c.Lock(ctx, key, ttl)
+	if err != nil {
+		sp.end(ctx, c, "DoOnce", err, -1)
+		return err
+	}
+	defer lock.Unlock(ctx)
+
+	err = fn(ctx)
+	sp.end(ctx, c, "DoOnce", err, -1)
+	return err
+}
@@ -0,0 +1,151 @@
+package rediscache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chi07/rediscache"
+)
+
+func TestLock_MutualExclusion(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestCache(t)
+
+	key := c.Key("lock", "rebuild")
+
+	l1, err := c.Lock(ctx, key, time.Second)
+	if err != nil {
+		t.Fatalf("Lock #1 error: %v", err)
+	}
+
+	// Một holder khác cố giành lock khi đang bị giữ → ErrLocked
+	_, err = c.Lock(ctx, key, time.Second)
+	if !errors.Is(err, rediscache.ErrLocked) {
+		t.Fatalf("Lock #2 expected ErrLocked; got %v", err)
+	}
+
+	if err := l1.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock error: %v", err)
+	}
+
+	// Sau khi unlock, worker khác phải giành được lock
+	l2, err := c.Lock(ctx, key, time.Second)
+	if err != nil {
+		t.Fatalf("Lock #3 after unlock error: %v", err)
+	}
+	_ = l2.Unlock(ctx)
+}
+
+func TestLock_TokenScopedRelease(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestCache(t)
+
+	key := c.Key("lock", "token_scoped")
+
+	l1, err := c.Lock(ctx, key, time.Second)
+	if err != nil {
+		t.Fatalf("Lock error: %v", err)
+	}
+
+	// Giả lập holder khác đang giữ key (value khác token của l1)
+	if err := c.RDB.Set(ctx, key, "someone-else-token", time.Second).Err(); err != nil {
+		t.Fatalf("prep Set error: %v", err)
+	}
+
+	// Unlock của l1 không được xoá key vì token không khớp
+	if err := l1.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock error: %v", err)
+	}
+
+	v, err := c.RDB.Get(ctx, key).Result()
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	if v != "someone-else-token" {
+		t.Fatalf("expected key untouched; got %q", v)
+	}
+}
+
+func TestLock_AutoRefreshKeepsTTLAlive(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestCache(t)
+
+	key := c.Key("lock", "auto_refresh")
+
+	// WithAutoRefresh gia hạn theo wall-clock thật (ticker mỗi ttl/3), còn
+	// miniredis chỉ giảm TTL khi gọi mr.FastForward. Vì vậy mỗi vòng test vừa
+	// để thời gian thật trôi qua (cho ticker chạy) vừa FastForward cùng một
+	// khoảng: không có refresh, key sẽ hết hạn sau khoảng 4 vòng.
+	ttl := 150 * time.Millisecond
+	l, err := c.Lock(ctx, key, ttl)
+	if err != nil {
+		t.Fatalf("Lock error: %v", err)
+	}
+	l.WithAutoRefresh(ctx)
+
+	deadline := time.Now().Add(5 * ttl)
+	for time.Now().Before(deadline) {
+		if !mr.Exists(key) {
+			t.Fatalf("key expired even though auto-refresh should keep it alive")
+		}
+		mr.FastForward(ttl / 4)
+		time.Sleep(ttl / 4)
+	}
+
+	if err := l.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock error: %v", err)
+	}
+}
+
+func TestDoOnce_ErrLockedWhenAlreadyHeld(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestCache(t)
+
+	key := c.Key("lock", "do_once")
+
+	l, err := c.Lock(ctx, key, time.Second)
+	if err != nil {
+		t.Fatalf("Lock error: %v", err)
+	}
+	defer l.Unlock(ctx)
+
+	called := false
+	err = c.DoOnce(ctx, key, time.Second, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, rediscache.ErrLocked) {
+		t.Fatalf("DoOnce expected ErrLocked; got %v", err)
+	}
+	if called {
+		t.Fatalf("fn should not be called when lock is already held")
+	}
+}
+
+func TestDoOnce_RunsFnAndReleases(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestCache(t)
+
+	key := c.Key("lock", "do_once_success")
+
+	called := false
+	err := c.DoOnce(ctx, key, time.Second, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("DoOnce error: %v", err)
+	}
+	if !called {
+		t.Fatalf("fn should have been called")
+	}
+
+	// Lock phải được release sau khi fn chạy xong
+	l2, err := c.Lock(ctx, key, time.Second)
+	if err != nil {
+		t.Fatalf("Lock after DoOnce error: %v", err)
+	}
+	_ = l2.Unlock(ctx)
+}
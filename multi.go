@@ -0,0 +1,169 @@
+package rediscache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// MultiError gom các lỗi unmarshal theo từng field/key riêng lẻ khi một
+// thao tác bulk (HGetJSONMulti, MGetSnapshots) xử lý nhiều phần tử: các
+// phần tử decode được vẫn nằm trong kết quả trả về, còn phần tử lỗi được
+// báo qua MultiError thay vì làm hỏng toàn bộ lời gọi.
+type MultiError struct {
+	Errors map[string]error
+}
+
+func (e *MultiError) Error() string {
+	if e == nil || len(e.Errors) == 0 {
+		return "rediscache: multi error"
+	}
+
+	parts := make([]string, 0, len(e.Errors))
+	for k, err := range e.Errors {
+		parts = append(parts, fmt.Sprintf("%s: %v", k, err))
+	}
+	sort.Strings(parts)
+	return "rediscache: multi error: " + strings.Join(parts, "; ")
+}
+
+// HGetJSONMulti đọc nhiều field của một hash trong một round-trip (HMGET)
+// rồi unmarshal từng value bằng codec đã cấu hình. Field không tồn tại
+// (redis trả nil) bị bỏ qua; lỗi unmarshal của từng field được gom vào
+// MultiError thay vì làm fail toàn bộ lời gọi.
+func HGetJSONMulti[T any](ctx context.Context, c *Cache, key string, fields []string) (map[string]T, error) {
+	ctx, sp := c.startOp(ctx, "HGetJSONMulti", attribute.String("key", key), attribute.Int("fields", len(fields)))
+
+	rc, cancel := context.WithTimeout(ctx, c.Opts.ReadTimeout)
+	defer cancel()
+
+	vals, err := c.RDB.HMGet(rc, key, fields...).Result()
+	if err != nil {
+		sp.end(ctx, c, "HGetJSONMulti", err, -1)
+		return nil, err
+	}
+
+	out := make(map[string]T, len(fields))
+	var multiErr *MultiError
+	size := 0
+
+	for i, field := range fields {
+		raw, ok := rawBytes(vals[i])
+		if !ok {
+			continue
+		}
+		size += len(raw)
+
+		var v T
+		if uErr := c.Opts.Codec.Unmarshal(raw, &v); uErr != nil {
+			if multiErr == nil {
+				multiErr = &MultiError{Errors: map[string]error{}}
+			}
+			multiErr.Errors[field] = uErr
+			continue
+		}
+		out[field] = v
+	}
+
+	var retErr error
+	if multiErr != nil {
+		retErr = multiErr
+	}
+	sp.end(ctx, c, "HGetJSONMulti", retErr, size)
+	return out, retErr
+}
+
+// MGetSnapshots đọc nhiều key trong một round-trip (MGET) rồi unmarshal
+// từng value bằng codec đã cấu hình. Key không tồn tại bị bỏ qua; lỗi
+// unmarshal của từng key được gom vào MultiError.
+func MGetSnapshots[T any](ctx context.Context, c *Cache, keys []string) (map[string]T, error) {
+	ctx, sp := c.startOp(ctx, "MGetSnapshots", attribute.Int("keys", len(keys)))
+
+	rc, cancel := context.WithTimeout(ctx, c.Opts.ReadTimeout)
+	defer cancel()
+
+	vals, err := c.RDB.MGet(rc, keys...).Result()
+	if err != nil {
+		sp.end(ctx, c, "MGetSnapshots", err, -1)
+		return nil, err
+	}
+
+	out := make(map[string]T, len(keys))
+	var multiErr *MultiError
+	size := 0
+
+	for i, key := range keys {
+		raw, ok := rawBytes(vals[i])
+		if !ok {
+			continue
+		}
+		size += len(raw)
+
+		var v T
+		if uErr := c.Opts.Codec.Unmarshal(raw, &v); uErr != nil {
+			if multiErr == nil {
+				multiErr = &MultiError{Errors: map[string]error{}}
+			}
+			multiErr.Errors[key] = uErr
+			continue
+		}
+		out[key] = v
+	}
+
+	var retErr error
+	if multiErr != nil {
+		retErr = multiErr
+	}
+	sp.end(ctx, c, "MGetSnapshots", retErr, size)
+	return out, retErr
+}
+
+// SetSnapshotsMulti ghi nhiều snapshot trong một round-trip, dùng Pipeline
+// với một SET kèm TTL c.Opts.TTL cho mỗi key (go-redis gửi PX khi TTL không
+// tròn giây, EX khi tròn giây), bọc trong PipelineTimeout giống các thao tác
+// pipeline khác của Cache.
+func (c *Cache) SetSnapshotsMulti(ctx context.Context, snapshots map[string]any) error {
+	ctx, sp := c.startOp(ctx, "SetSnapshotsMulti", attribute.Int("keys", len(snapshots)))
+
+	rc, cancel := context.WithTimeout(ctx, c.Opts.PipelineTimeout)
+	defer cancel()
+
+	pipe := c.RDB.Pipeline()
+	size := 0
+	for key, snapshot := range snapshots {
+		b, err := c.Opts.Codec.Marshal(snapshot)
+		if err != nil {
+			sp.end(ctx, c, "SetSnapshotsMulti", err, size)
+			return err
+		}
+		size += len(b)
+		pipe.Set(rc, key, b, c.Opts.TTL)
+	}
+
+	_, err := pipe.Exec(rc)
+	if err == nil {
+		for key := range snapshots {
+			c.invalidateLocal(key)
+		}
+	}
+	sp.end(ctx, c, "SetSnapshotsMulti", err, size)
+	return err
+}
+
+// rawBytes chuyển kết quả thô của HMGet/MGet (string, []byte, hoặc nil khi
+// field/key không tồn tại) về []byte, bool để xử lý đồng nhất.
+func rawBytes(v any) ([]byte, bool) {
+	switch vv := v.(type) {
+	case nil:
+		return nil, false
+	case string:
+		return []byte(vv), true
+	case []byte:
+		return vv, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,115 @@
+package rediscache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chi07/rediscache"
+)
+
+func TestHGetJSONMulti(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestCache(t)
+
+	type Group struct {
+		ID int `json:"id"`
+	}
+
+	key := c.Key("multi", "hash")
+	if err := c.AtomicReplaceHashJSON(ctx, key, map[string]any{
+		"9": Group{ID: 9},
+		"3": Group{ID: 3},
+	}); err != nil {
+		t.Fatalf("AtomicReplaceHashJSON error: %v", err)
+	}
+
+	out, err := rediscache.HGetJSONMulti[Group](ctx, c, key, []string{"9", "3", "404"})
+	if err != nil {
+		t.Fatalf("HGetJSONMulti error: %v", err)
+	}
+	if len(out) != 2 || out["9"].ID != 9 || out["3"].ID != 3 {
+		t.Fatalf("unexpected result: %+v", out)
+	}
+	if _, ok := out["404"]; ok {
+		t.Fatalf("expected missing field to be absent from result")
+	}
+}
+
+func TestHGetJSONMulti_PartialUnmarshalError(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestCache(t)
+
+	type Group struct {
+		ID int `json:"id"`
+	}
+
+	key := c.Key("multi", "hash_bad")
+	if err := c.RDB.HSet(ctx, key, "good", `{"id":1}`, "bad", "not-json").Err(); err != nil {
+		t.Fatalf("prep HSet error: %v", err)
+	}
+
+	out, err := rediscache.HGetJSONMulti[Group](ctx, c, key, []string{"good", "bad"})
+	var multiErr *rediscache.MultiError
+	if !errors.As(err, &multiErr) {
+		t.Fatalf("expected *MultiError; got %v", err)
+	}
+	if _, ok := multiErr.Errors["bad"]; !ok {
+		t.Fatalf("expected MultiError to contain \"bad\" field; got %+v", multiErr.Errors)
+	}
+	if out["good"].ID != 1 {
+		t.Fatalf("expected good field to still decode: %+v", out)
+	}
+}
+
+func TestMGetSnapshots(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestCache(t)
+
+	type Snapshot struct {
+		Data int `json:"data"`
+	}
+
+	k1, k2 := c.Key("multi", "s1"), c.Key("multi", "s2")
+	if err := c.SetSnapshot(ctx, k1, Snapshot{Data: 1}); err != nil {
+		t.Fatalf("SetSnapshot k1 error: %v", err)
+	}
+	if err := c.SetSnapshot(ctx, k2, Snapshot{Data: 2}); err != nil {
+		t.Fatalf("SetSnapshot k2 error: %v", err)
+	}
+
+	out, err := rediscache.MGetSnapshots[Snapshot](ctx, c, []string{k1, k2, c.Key("multi", "missing")})
+	if err != nil {
+		t.Fatalf("MGetSnapshots error: %v", err)
+	}
+	if len(out) != 2 || out[k1].Data != 1 || out[k2].Data != 2 {
+		t.Fatalf("unexpected result: %+v", out)
+	}
+}
+
+func TestSetSnapshotsMulti(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestCache(t)
+
+	type Snapshot struct {
+		Data int `json:"data"`
+	}
+
+	k1, k2 := c.Key("multi", "set1"), c.Key("multi", "set2")
+	err := c.SetSnapshotsMulti(ctx, map[string]any{
+		k1: Snapshot{Data: 1},
+		k2: Snapshot{Data: 2},
+	})
+	if err != nil {
+		t.Fatalf("SetSnapshotsMulti error: %v", err)
+	}
+
+	got1, ok, err := rediscache.TryGetSnapshot[Snapshot](ctx, c, k1)
+	if err != nil || !ok || got1.Data != 1 {
+		t.Fatalf("TryGetSnapshot k1 = %+v ok=%v err=%v", got1, ok, err)
+	}
+	got2, ok, err := rediscache.TryGetSnapshot[Snapshot](ctx, c, k2)
+	if err != nil || !ok || got2.Data != 2 {
+		t.Fatalf("TryGetSnapshot k2 = %+v ok=%v err=%v", got2, ok, err)
+	}
+}
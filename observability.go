@@ -0,0 +1,164 @@
+package rediscache
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// instruments nhóm các OTel metric instrument được tạo một lần khi
+// Options.Meter được set. Giữ nil (không tạo) nếu người dùng không cấu hình
+// Meter, để mặc định không tốn chi phí.
+type instruments struct {
+	opsTotal     metric.Int64Counter
+	opDuration   metric.Float64Histogram
+	payloadBytes metric.Int64Histogram
+	cacheResult  metric.Int64Counter
+	redisRTT     metric.Float64Histogram
+}
+
+func newInstruments(meter metric.Meter) *instruments {
+	if meter == nil {
+		return nil
+	}
+
+	ins := &instruments{}
+	ins.opsTotal, _ = meter.Int64Counter(
+		"rediscache_ops_total",
+		metric.WithDescription("Total number of rediscache operations, labeled by op and result"),
+	)
+	ins.opDuration, _ = meter.Float64Histogram(
+		"rediscache_op_duration_seconds",
+		metric.WithDescription("Duration of rediscache operations in seconds"),
+		metric.WithUnit("s"),
+	)
+	ins.payloadBytes, _ = meter.Int64Histogram(
+		"rediscache_payload_bytes",
+		metric.WithDescription("Size in bytes of values written/read through rediscache"),
+		metric.WithUnit("By"),
+	)
+	ins.cacheResult, _ = meter.Int64Counter(
+		"rediscache_cache_result_total",
+		metric.WithDescription("Hit/miss counter for read methods (TryGetSnapshot, HGetJSON, HGetString)"),
+	)
+	ins.redisRTT, _ = meter.Float64Histogram(
+		"rediscache_redis_rtt_seconds",
+		metric.WithDescription("Round-trip time of the underlying Redis commands"),
+		metric.WithUnit("s"),
+	)
+	return ins
+}
+
+// span bọc một (trace.Span, thời điểm bắt đầu) nil-safe: khi Options.Tracer
+// không được set, s là nil và end() chỉ còn ghi metric.
+type span struct {
+	s     trace.Span
+	start time.Time
+}
+
+// startOp mở một span (nếu có Tracer) và đánh dấu thời điểm bắt đầu cho
+// metric. op là tên method công khai, vd "AtomicReplaceHash".
+func (c *Cache) startOp(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, *span) {
+	start := time.Now()
+	if c.Opts.Tracer == nil {
+		return ctx, &span{start: start}
+	}
+
+	var s trace.Span
+	ctx, s = c.Opts.Tracer.Start(ctx, "rediscache."+op, trace.WithAttributes(attrs...))
+	return ctx, &span{s: s, start: start}
+}
+
+// end đóng span (nếu có) và ghi các metric opsTotal/opDuration/payloadBytes
+// cho op; bytes >= 0 cũng được gắn lên span dưới attribute "bytes". bytes < 0
+// nghĩa là không áp dụng (không ghi payloadBytes, không gắn attribute).
+func (sp *span) end(ctx context.Context, c *Cache, op string, err error, bytes int) {
+	dur := time.Since(sp.start)
+
+	if sp.s != nil {
+		if bytes >= 0 {
+			sp.s.SetAttributes(attribute.Int("bytes", bytes))
+		}
+		if err != nil {
+			sp.s.RecordError(err)
+			sp.s.SetStatus(codes.Error, err.Error())
+		}
+		sp.s.End()
+	}
+
+	if c.obs == nil {
+		return
+	}
+
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+
+	c.obs.opsTotal.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("op", op),
+		attribute.String("result", result),
+	))
+	c.obs.opDuration.Record(ctx, dur.Seconds(), metric.WithAttributes(attribute.String("op", op)))
+	if bytes >= 0 {
+		c.obs.payloadBytes.Record(ctx, int64(bytes), metric.WithAttributes(attribute.String("op", op)))
+	}
+}
+
+// recordHitMiss ghi nhận hit/miss cho các method đọc (TryGetSnapshot,
+// HGetJSON, HGetString).
+func (c *Cache) recordHitMiss(ctx context.Context, op string, hit bool) {
+	if c.obs == nil {
+		return
+	}
+
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	c.obs.cacheResult.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("op", op),
+		attribute.String("result", result),
+	))
+}
+
+// metricsHook là redis.Hook ghi RTT của các lệnh Redis thật sự, để timing
+// phản ánh round-trip tới server thay vì chỉ thời gian của method bọc ngoài.
+type metricsHook struct {
+	c *Cache
+}
+
+func (h metricsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h metricsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.record(ctx, cmd.Name(), time.Since(start))
+		return err
+	}
+}
+
+func (h metricsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.record(ctx, "pipeline", time.Since(start))
+		return err
+	}
+}
+
+func (h metricsHook) record(ctx context.Context, cmdName string, dur time.Duration) {
+	if h.c.obs == nil {
+		return
+	}
+	h.c.obs.redisRTT.Record(ctx, dur.Seconds(), metric.WithAttributes(attribute.String("cmd", cmdName)))
+}
@@ -0,0 +1,180 @@
+package rediscache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/chi07/rediscache"
+)
+
+func TestObservability_NoopByDefault(t *testing.T) {
+	c, _ := newTestCache(t)
+
+	if c.Opts.Tracer != nil || c.Opts.Meter != nil {
+		t.Fatalf("expected Tracer and Meter to be nil by default")
+	}
+
+	// Không panic khi không có Tracer/Meter được cấu hình
+	if err := c.SetSnapshot(context.Background(), c.Key("obs", "noop"), map[string]int{"a": 1}); err != nil {
+		t.Fatalf("SetSnapshot error: %v", err)
+	}
+}
+
+func TestObservability_TracerRecordsSpans(t *testing.T) {
+	ctx := context.Background()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := tp.Tracer("rediscache-test")
+
+	c := rediscache.New(rdb, rediscache.Options{
+		KeyPrefix: "test",
+		TTL:       time.Minute,
+		Tracer:    tracer,
+	})
+
+	key := c.Key("obs", "span")
+	if err := c.SetSnapshot(ctx, key, map[string]int{"a": 1}); err != nil {
+		t.Fatalf("SetSnapshot error: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) == 0 {
+		t.Fatalf("expected at least one recorded span")
+	}
+	if spans[0].Name() != "rediscache.SetSnapshot" {
+		t.Fatalf("span name = %q; want rediscache.SetSnapshot", spans[0].Name())
+	}
+}
+
+func TestObservability_LockAndGetOrLoadRecordSpans(t *testing.T) {
+	ctx := context.Background()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := tp.Tracer("rediscache-test")
+
+	c := rediscache.New(rdb, rediscache.Options{
+		KeyPrefix: "test",
+		TTL:       time.Minute,
+		Tracer:    tracer,
+	})
+
+	lock, err := c.Lock(ctx, c.Key("obs", "lock"), time.Second)
+	if err != nil {
+		t.Fatalf("Lock error: %v", err)
+	}
+	if err := lock.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock error: %v", err)
+	}
+
+	if err := c.DoOnce(ctx, c.Key("obs", "doonce"), time.Second, func(ctx context.Context) error {
+		return nil
+	}); err != nil {
+		t.Fatalf("DoOnce error: %v", err)
+	}
+
+	key := c.Key("obs", "getorload")
+	loader := func(ctx context.Context) (string, error) { return "v", nil }
+	if _, err := rediscache.GetOrLoad(ctx, c, key, loader, rediscache.LoadOptions{}); err != nil {
+		t.Fatalf("GetOrLoad error: %v", err)
+	}
+
+	var names []string
+	for _, s := range recorder.Ended() {
+		names = append(names, s.Name())
+	}
+
+	want := []string{
+		"rediscache.Lock",
+		"rediscache.Unlock",
+		"rediscache.DoOnce",
+		"rediscache.GetOrLoad",
+	}
+	for _, w := range want {
+		found := false
+		for _, n := range names {
+			if n == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("expected span %q to be recorded; got %v", w, names)
+		}
+	}
+}
+
+func TestObservability_MeterRecordsOpsAndHitMiss(t *testing.T) {
+	ctx := context.Background()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(metric.WithReader(reader))
+	meter := mp.Meter("rediscache-test")
+
+	c := rediscache.New(rdb, rediscache.Options{
+		KeyPrefix: "test",
+		TTL:       time.Minute,
+		Meter:     meter,
+	})
+
+	key := c.Key("obs", "meter")
+	if err := c.SetSnapshot(ctx, key, map[string]int{"a": 1}); err != nil {
+		t.Fatalf("SetSnapshot error: %v", err)
+	}
+	if _, _, err := rediscache.TryGetSnapshot[map[string]int](ctx, c, key); err != nil {
+		t.Fatalf("TryGetSnapshot error: %v", err)
+	}
+	if _, _, err := rediscache.TryGetSnapshot[map[string]int](ctx, c, c.Key("obs", "missing")); err != nil {
+		t.Fatalf("TryGetSnapshot (miss) error: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("Collect error: %v", err)
+	}
+
+	var names []string
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			names = append(names, m.Name)
+		}
+	}
+
+	want := []string{
+		"rediscache_ops_total",
+		"rediscache_op_duration_seconds",
+		"rediscache_payload_bytes",
+		"rediscache_cache_result_total",
+	}
+	for _, w := range want {
+		found := false
+		for _, n := range names {
+			if n == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("expected metric %q to be recorded; got %v", w, names)
+		}
+	}
+}
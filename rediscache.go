@@ -2,14 +2,19 @@ package rediscache
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 
-	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
 	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
 )
 
 type Options struct {
@@ -18,6 +23,30 @@ type Options struct {
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	PipelineTimeout time.Duration
+	// Codec điều khiển cách snapshot/hash values được marshal/unmarshal.
+	// Mặc định là JSONCodec{} nếu không set.
+	Codec Codec
+	// Tracer, nếu set, bọc mỗi public method trong một span OTel với
+	// attribute key/field/byte size/TTL. Mặc định nil (không trace).
+	Tracer trace.Tracer
+	// Meter, nếu set, dùng để tạo counter/histogram Prometheus-style
+	// (rediscache_ops_total, rediscache_op_duration_seconds, ...) và một
+	// hit/miss counter cho các method đọc. Mặc định nil (không đo).
+	Meter metric.Meter
+	// LocalCache, nếu Size > 0, bật một in-process LRU phía trước
+	// TryGetSnapshot/HGetJSON/HGetString, được đồng bộ xoá giữa các instance
+	// Cache qua Pub/Sub (xem localcache.go). Mặc định tắt.
+	LocalCache LocalCacheOptions
+}
+
+// LocalCacheOptions cấu hình near cache trong process, đứng trước Redis.
+type LocalCacheOptions struct {
+	// Size là số entry tối đa trong LRU. <= 0 nghĩa là tắt local cache.
+	Size int
+	// TTL là thời gian sống tối đa của một entry trong LRU, độc lập với TTL
+	// trên Redis. <= 0 nghĩa là entry không tự hết hạn (chỉ bị evict theo LRU
+	// hoặc theo invalidation).
+	TTL time.Duration
 }
 
 func (o Options) withDefaults() Options {
@@ -36,16 +65,70 @@ func (o Options) withDefaults() Options {
 	if o.PipelineTimeout <= 0 {
 		o.PipelineTimeout = 1 * time.Second
 	}
+	if o.Codec == nil {
+		o.Codec = JSONCodec{}
+	}
 	return o
 }
 
 type Cache struct {
-	RDB  *redis.Client
+	RDB  redis.UniversalClient
 	Opts Options
+
+	// loadGroup coalesces concurrent GetOrLoad misses on the same key so
+	// only one caller invokes the loader (see getorload.go).
+	loadGroup singleflight.Group
+
+	// obs holds the OTel metric instruments created from Opts.Meter, or nil
+	// if Opts.Meter was not set (see observability.go).
+	obs *instruments
+
+	// nodeID identifies this Cache instance in invalidation Pub/Sub messages
+	// so it can ignore the events it published itself (see localcache.go).
+	nodeID string
+	// local is the in-process near cache fronting reads, or nil if
+	// Opts.LocalCache.Size <= 0.
+	local *expirable.LRU[string, []byte]
+	sub   *redis.PubSub
+
+	invalidateMu      sync.Mutex
+	pendingInvalidate map[string]struct{}
+	invalidateTimer   *time.Timer
 }
 
 func New(rdb *redis.Client, opts Options) *Cache {
-	return &Cache{RDB: rdb, Opts: opts.withDefaults()}
+	return newCache(rdb, opts)
+}
+
+// NewUniversal giống New nhưng nhận redis.UniversalClient, cho phép Cache
+// chạy trên cả standalone client (*redis.Client) lẫn *redis.ClusterClient
+// hoặc *redis.Ring.
+func NewUniversal(rdb redis.UniversalClient, opts Options) *Cache {
+	return newCache(rdb, opts)
+}
+
+func newCache(rdb redis.UniversalClient, opts Options) *Cache {
+	opts = opts.withDefaults()
+	c := &Cache{RDB: rdb, Opts: opts, obs: newInstruments(opts.Meter)}
+	if c.obs != nil {
+		rdb.AddHook(metricsHook{c: c})
+	}
+	if opts.LocalCache.Size > 0 {
+		c.startLocalCache()
+	}
+	return c
+}
+
+// Close dừng subscription invalidation của local cache (nếu có). Nó không
+// đóng RDB, vì Cache không sở hữu vòng đời của client Redis truyền vào.
+func (c *Cache) Close() error {
+	if c.invalidateTimer != nil {
+		c.invalidateTimer.Stop()
+	}
+	if c.sub != nil {
+		return c.sub.Close()
+	}
+	return nil
 }
 
 func (c *Cache) Key(parts ...string) string {
@@ -76,78 +159,75 @@ func Normalize(s string) string {
 
 // ---------- Atomic writers (methods, NON-generic) ----------
 
+// AtomicReplaceHash thay toàn bộ nội dung hash tại finalKey bằng kv, dùng
+// replaceHashScript để HSET+RENAME+PEXPIRE chạy atomically trên server và
+// an toàn trên Redis Cluster (xem cluster.go).
 func (c *Cache) AtomicReplaceHash(ctx context.Context, finalKey string, kv map[string]string) error {
-	rc, cancel := context.WithTimeout(ctx, c.Opts.PipelineTimeout)
-	defer cancel()
-
-	tmpKey := finalKey + ":tmp:" + uuid.NewString()
-	pipe := c.RDB.Pipeline()
-
-	// 1) Tạo tmpKey
-	dummy := false
-	if len(kv) > 0 {
-		args := make([]any, 0, len(kv)*2)
-		for k, v := range kv {
-			args = append(args, k, v)
-		}
-		pipe.HSet(rc, tmpKey, args...)
-	} else {
-		// Đảm bảo tmpKey tồn tại để RENAME không lỗi
-		pipe.HSet(rc, tmpKey, "___", "___")
-		dummy = true
+	ctx, sp := c.startOp(ctx, "AtomicReplaceHash",
+		attribute.String("key", finalKey),
+		attribute.Int("fields", len(kv)),
+		attribute.Int64("ttl_ms", c.Opts.TTL.Milliseconds()),
+	)
+
+	fields := make([]any, 0, len(kv)*2)
+	for k, v := range kv {
+		fields = append(fields, k, v)
 	}
-
-	// 2) Đổi tên sang finalKey + TTL
-	pipe.Rename(rc, tmpKey, finalKey)
-	pipe.Expire(rc, finalKey, c.Opts.TTL)
-
-	// 3) Nếu dùng dummy, xóa field dummy TRÊN finalKey sau khi rename
-	if dummy {
-		pipe.HDel(rc, finalKey, "___")
+	err := c.runAtomicReplaceHash(ctx, finalKey, fields)
+	if err == nil {
+		c.invalidateLocal(finalKey)
 	}
 
-	_, err := pipe.Exec(rc)
+	sp.end(ctx, c, "AtomicReplaceHash", err, -1)
 	return err
 }
 
+// AtomicReplaceHashJSON giống AtomicReplaceHash nhưng marshal từng value
+// bằng codec đã cấu hình trước khi ghi vào hash.
 func (c *Cache) AtomicReplaceHashJSON(ctx context.Context, finalKey string, objs map[string]any) error {
-	rc, cancel := context.WithTimeout(ctx, c.Opts.PipelineTimeout)
-	defer cancel()
-
-	tmpKey := finalKey + ":tmp:" + uuid.NewString()
-	pipe := c.RDB.Pipeline()
-
-	dummy := false
-	if len(objs) > 0 {
-		for id, obj := range objs {
-			b, _ := json.Marshal(obj) // best-effort
-			pipe.HSet(rc, tmpKey, id, b)
-		}
-	} else {
-		pipe.HSet(rc, tmpKey, "___", "___")
-		dummy = true
+	ctx, sp := c.startOp(ctx, "AtomicReplaceHashJSON",
+		attribute.String("key", finalKey),
+		attribute.Int("fields", len(objs)),
+		attribute.Int64("ttl_ms", c.Opts.TTL.Milliseconds()),
+	)
+
+	fields := make([]any, 0, len(objs)*2)
+	size := 0
+	for id, obj := range objs {
+		b, _ := c.Opts.Codec.Marshal(obj) // best-effort
+		size += len(b)
+		fields = append(fields, id, b)
 	}
-
-	pipe.Rename(rc, tmpKey, finalKey)
-	pipe.Expire(rc, finalKey, c.Opts.TTL)
-
-	if dummy {
-		pipe.HDel(rc, finalKey, "___")
+	err := c.runAtomicReplaceHash(ctx, finalKey, fields)
+	if err == nil {
+		c.invalidateLocal(finalKey)
 	}
 
-	_, err := pipe.Exec(rc)
+	sp.end(ctx, c, "AtomicReplaceHashJSON", err, size)
 	return err
 }
 
 func (c *Cache) SetSnapshot(ctx context.Context, key string, snapshot any) error {
-	rc, cancel := context.WithTimeout(ctx, c.Opts.WriteTimeout)
-	defer cancel()
+	ctx, sp := c.startOp(ctx, "SetSnapshot",
+		attribute.String("key", key),
+		attribute.Int64("ttl_ms", c.Opts.TTL.Milliseconds()),
+	)
 
-	b, err := json.Marshal(snapshot)
+	b, err := c.Opts.Codec.Marshal(snapshot)
 	if err != nil {
+		sp.end(ctx, c, "SetSnapshot", err, 0)
 		return err
 	}
-	return c.RDB.Set(rc, key, b, c.Opts.TTL).Err()
+
+	rc, cancel := context.WithTimeout(ctx, c.Opts.WriteTimeout)
+	defer cancel()
+
+	err = c.RDB.Set(rc, key, b, c.Opts.TTL).Err()
+	if err == nil {
+		c.invalidateLocal(key)
+	}
+	sp.end(ctx, c, "SetSnapshot", err, len(b))
+	return err
 }
 
 // ---------- Generic FUNCTIONS ----------
@@ -157,59 +237,110 @@ func (c *Cache) SetSnapshot(ctx context.Context, key string, snapshot any) error
 func TryGetSnapshot[T any](ctx context.Context, c *Cache, key string) (T, bool, error) {
 	var zero T
 
+	ctx, sp := c.startOp(ctx, "TryGetSnapshot", attribute.String("key", key))
+
+	if raw, ok := c.localGet(localSnapshotKey(key)); ok {
+		var out T
+		if uErr := c.Opts.Codec.Unmarshal(raw, &out); uErr == nil {
+			c.recordHitMiss(ctx, "TryGetSnapshot", true)
+			sp.end(ctx, c, "TryGetSnapshot", nil, len(raw))
+			return out, true, nil
+		}
+		// Entry local hỏng (hiếm khi xảy ra): bỏ qua và đọc lại từ Redis.
+	}
+
 	rc, cancel := context.WithTimeout(ctx, c.Opts.ReadTimeout)
 	defer cancel()
 
 	raw, err := c.RDB.Get(rc, key).Bytes()
 	if errors.Is(err, redis.Nil) {
+		c.recordHitMiss(ctx, "TryGetSnapshot", false)
+		sp.end(ctx, c, "TryGetSnapshot", nil, 0)
 		return zero, false, nil
 	}
 	if err != nil {
+		sp.end(ctx, c, "TryGetSnapshot", err, 0)
 		return zero, false, err
 	}
+	c.recordHitMiss(ctx, "TryGetSnapshot", true)
 
 	var out T
-	if uErr := json.Unmarshal(raw, &out); uErr != nil {
+	if uErr := c.Opts.Codec.Unmarshal(raw, &out); uErr != nil {
+		sp.end(ctx, c, "TryGetSnapshot", uErr, len(raw))
 		return zero, false, uErr
 	}
+	c.localSet(localSnapshotKey(key), raw)
+	sp.end(ctx, c, "TryGetSnapshot", nil, len(raw))
 	return out, true, nil
 }
 
-// HGetJSON: HGET field rồi unmarshal ra T
+// HGetJSON: HGET field rồi unmarshal ra T bằng codec đã cấu hình
 
 func HGetJSON[T any](ctx context.Context, c *Cache, key, field string) (T, bool, error) {
 	var zero T
 
+	ctx, sp := c.startOp(ctx, "HGetJSON", attribute.String("key", key), attribute.String("field", field))
+
+	if raw, ok := c.localGet(localHashKey(key, field)); ok {
+		var out T
+		if uErr := c.Opts.Codec.Unmarshal(raw, &out); uErr == nil {
+			c.recordHitMiss(ctx, "HGetJSON", true)
+			sp.end(ctx, c, "HGetJSON", nil, len(raw))
+			return out, true, nil
+		}
+	}
+
 	rc, cancel := context.WithTimeout(ctx, c.Opts.ReadTimeout)
 	defer cancel()
 
 	raw, err := c.RDB.HGet(rc, key, field).Bytes()
 	if errors.Is(err, redis.Nil) {
+		c.recordHitMiss(ctx, "HGetJSON", false)
+		sp.end(ctx, c, "HGetJSON", nil, 0)
 		return zero, false, nil
 	}
 	if err != nil {
+		sp.end(ctx, c, "HGetJSON", err, 0)
 		return zero, false, err
 	}
+	c.recordHitMiss(ctx, "HGetJSON", true)
 
 	var out T
-	if uErr := json.Unmarshal(raw, &out); uErr != nil {
+	if uErr := c.Opts.Codec.Unmarshal(raw, &out); uErr != nil {
+		sp.end(ctx, c, "HGetJSON", uErr, len(raw))
 		return zero, false, uErr
 	}
+	c.localSet(localHashKey(key, field), raw)
+	sp.end(ctx, c, "HGetJSON", nil, len(raw))
 	return out, true, nil
 }
 
 // HGetString: HGET field trả về string (non-generic)
 
 func (c *Cache) HGetString(ctx context.Context, key, field string) (string, bool, error) {
+	ctx, sp := c.startOp(ctx, "HGetString", attribute.String("key", key), attribute.String("field", field))
+
+	if raw, ok := c.localGet(localHashKey(key, field)); ok {
+		c.recordHitMiss(ctx, "HGetString", true)
+		sp.end(ctx, c, "HGetString", nil, len(raw))
+		return string(raw), true, nil
+	}
+
 	rc, cancel := context.WithTimeout(ctx, c.Opts.ReadTimeout)
 	defer cancel()
 
 	v, err := c.RDB.HGet(rc, key, field).Result()
 	if errors.Is(err, redis.Nil) {
+		c.recordHitMiss(ctx, "HGetString", false)
+		sp.end(ctx, c, "HGetString", nil, 0)
 		return "", false, nil
 	}
 	if err != nil {
+		sp.end(ctx, c, "HGetString", err, 0)
 		return "", false, err
 	}
+	c.recordHitMiss(ctx, "HGetString", true)
+	c.localSet(localHashKey(key, field), []byte(v))
+	sp.end(ctx, c, "HGetString", nil, len(v))
 	return v, true, nil
 }